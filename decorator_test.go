@@ -0,0 +1,83 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+var goldenTests = []struct {
+	name    string
+	pattern string
+	iface   string
+	style   hookStyle
+	lang    string
+	golden  string
+}{
+	{
+		name:    "generic interface",
+		pattern: "./testdata/generics",
+		iface:   "Repo",
+		style:   hookStyleSimple,
+		golden:  "testdata/generics/repo_decorator.golden",
+	},
+	{
+		name:    "embedded interfaces",
+		pattern: "./testdata/embedding",
+		iface:   "Sample",
+		style:   hookStyleSimple,
+		golden:  "testdata/embedding/sample_decorator.golden",
+	},
+	{
+		name:    "rich hook",
+		pattern: "./testdata/richhook",
+		iface:   "Sample",
+		style:   hookStyleRich,
+		golden:  "testdata/richhook/sample_decorator.golden",
+	},
+	{
+		name:    "any normalization from go.mod",
+		pattern: "./testdata/anytype",
+		iface:   "Sample",
+		style:   hookStyleSimple,
+		golden:  "testdata/anytype/sample_decorator.golden",
+	},
+	{
+		name:    "any normalization disabled for -lang=1.17",
+		pattern: "./testdata/anytype",
+		iface:   "Sample",
+		style:   hookStyleSimple,
+		lang:    "1.17",
+		golden:  "testdata/anytype/sample_decorator_1.17.golden",
+	},
+}
+
+func TestGoldenFiles(t *testing.T) {
+	for _, tt := range goldenTests {
+		t.Run(tt.name, func(t *testing.T) {
+			pkgs := loadPackages(tt.pattern)
+			obj, pkg := findInterface(pkgs, tt.iface)
+
+			useAny := supportsAny(resolveLangVersion(tt.lang, packageDir(pkg)))
+			dec := buildDecorator(obj, pkg, tt.style, useAny)
+			got := writeDecorator(dec)
+
+			if *update {
+				if err := os.WriteFile(tt.golden, []byte(got), 0o644); err != nil {
+					t.Fatalf("writing golden file: %v", err)
+				}
+			}
+
+			want, err := os.ReadFile(tt.golden)
+			if err != nil {
+				t.Fatalf("reading golden file: %v", err)
+			}
+
+			if got != string(want) {
+				t.Errorf("generated decorator does not match golden file\ngot:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
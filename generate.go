@@ -0,0 +1,75 @@
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// generate builds a decorator for every requested type and writes the
+// result to disk: one file per type using the <type>_decorator.go
+// convention stringer and other //go:generate tools follow, or a single
+// combined file when -output is set.
+func generate(pkgs []*packages.Package, typeNames []string, style hookStyle, output, lang string) {
+	type built struct {
+		dec  *decorator
+		pkg  *packages.Package
+		name string
+	}
+
+	all := make([]built, len(typeNames))
+	for i, name := range typeNames {
+		obj, pkg := findInterface(pkgs, name)
+		useAny := supportsAny(resolveLangVersion(lang, packageDir(pkg)))
+		all[i] = built{dec: buildDecorator(obj, pkg, style, useAny), pkg: pkg, name: obj.Name()}
+	}
+
+	if output != "" {
+		decs := make([]*decorator, len(all))
+		for i, b := range all {
+			decs[i] = b.dec
+		}
+		writeGeneratedFile(output, renderFile(decs))
+		return
+	}
+
+	for _, b := range all {
+		writeGeneratedFile(defaultOutputPath(b.pkg, b.name), renderFile([]*decorator{b.dec}))
+	}
+}
+
+// defaultOutputPath matches the convention stringer and other x/tools
+// generators use: <lowercased-typename>_decorator.go next to the source.
+func defaultOutputPath(pkg *packages.Package, typeName string) string {
+	return filepath.Join(packageDir(pkg), strings.ToLower(typeName)+"_decorator.go")
+}
+
+// packageDir returns the directory holding pkg's source, used both to
+// place the default output file and to look up its go.mod.
+func packageDir(pkg *packages.Package) string {
+	if len(pkg.GoFiles) == 0 {
+		return "."
+	}
+	return filepath.Dir(pkg.GoFiles[0])
+}
+
+func writeGeneratedFile(path, contents string) {
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		log.Fatalf("failed to write %s: %v", path, err)
+	}
+	log.Printf("wrote %s", path)
+}
+
+// splitTypeNames splits the -type flag's comma-separated list, e.g.
+// "A,B,C", trimming stray whitespace around each name.
+func splitTypeNames(s string) []string {
+	parts := strings.Split(s, ",")
+	names := make([]string, len(parts))
+	for i, p := range parts {
+		names[i] = strings.TrimSpace(p)
+	}
+	return names
+}
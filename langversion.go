@@ -0,0 +1,96 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+// resolveLangVersion returns the effective Go language version (e.g.
+// "1.21") used to decide whether `interface{}` should be normalized to
+// `any`. An explicit -lang flag wins; otherwise it's read from the `go`
+// directive of the go.mod governing pkgDir, found by walking up parent
+// directories the way the go command itself locates a module root. An
+// unparseable or missing go.mod returns "", which leaves normalization
+// off - matching pre-1.18 behavior.
+func resolveLangVersion(explicit, pkgDir string) string {
+	if explicit != "" {
+		return explicit
+	}
+
+	path := findGoMod(pkgDir)
+	if path == "" {
+		return ""
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+
+	mf, err := modfile.Parse("go.mod", data, nil)
+	if err != nil || mf.Go == nil {
+		return ""
+	}
+
+	return mf.Go.Version
+}
+
+// findGoMod walks up from dir looking for a go.mod, returning its path or
+// "" if none is found before the filesystem root.
+func findGoMod(dir string) string {
+	if dir == "" {
+		dir = "."
+	}
+
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+
+	for {
+		candidate := filepath.Join(dir, "go.mod")
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// supportsAny reports whether lang (e.g. "1.18", "1.21.6") is 1.18 or
+// later, the first release where `any` is idiomatic in place of the
+// empty interface literal.
+func supportsAny(lang string) bool {
+	major, minor, ok := parseGoVersion(lang)
+	if !ok {
+		return false
+	}
+	return major > 1 || (major == 1 && minor >= 18)
+}
+
+func parseGoVersion(v string) (major, minor int, ok bool) {
+	parts := strings.SplitN(v, ".", 3)
+	if len(parts) < 2 {
+		return 0, 0, false
+	}
+
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, false
+	}
+
+	minor, err = strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, false
+	}
+
+	return major, minor, true
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"go/types"
+	"log"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// loadMode requests everything writeDecorator needs to render fully
+// qualified types: names, syntax for position info, and complete type
+// information for the package and everything it depends on.
+const loadMode = packages.NeedName | packages.NeedFiles | packages.NeedTypes |
+	packages.NeedTypesInfo | packages.NeedSyntax | packages.NeedDeps
+
+// loadPackages loads the package(s) matching pattern with full type
+// information, in the same way gopls and staticcheck do.
+func loadPackages(pattern string) []*packages.Package {
+	cfg := &packages.Config{Mode: loadMode}
+	pkgs, err := packages.Load(cfg, pattern)
+	if err != nil {
+		log.Fatalf("failed to load %s: %v", pattern, err)
+	}
+	if packages.PrintErrors(pkgs) > 0 {
+		log.Fatalf("errors loading %s", pattern)
+	}
+	return pkgs
+}
+
+// findInterface resolves the -type value against the loaded packages.
+// The value may be a bare interface name, matched against every loaded
+// package, or a "pkg.Name" selector that pins the lookup to the package
+// whose name or import path is pkg.
+func findInterface(pkgs []*packages.Package, typeName string) (*types.TypeName, *packages.Package) {
+	pkgSel, name := splitSelector(typeName)
+
+	for _, pkg := range pkgs {
+		if pkgSel != "" && pkg.Name != pkgSel && pkg.PkgPath != pkgSel {
+			continue
+		}
+
+		obj := pkg.Types.Scope().Lookup(name)
+		if obj == nil {
+			continue
+		}
+
+		typeNameObj, ok := obj.(*types.TypeName)
+		if !ok {
+			continue
+		}
+
+		if _, ok := typeNameObj.Type().Underlying().(*types.Interface); !ok {
+			log.Fatalf("%s is not an interface", typeName)
+		}
+
+		return typeNameObj, pkg
+	}
+
+	log.Fatalf("interface %s not found", typeName)
+	return nil, nil
+}
+
+// splitSelector splits "pkg.Name" into ("pkg", "Name"). A bare name is
+// returned as ("", name).
+func splitSelector(typeName string) (string, string) {
+	if idx := strings.LastIndex(typeName, "."); idx > -1 {
+		return typeName[:idx], typeName[idx+1:]
+	}
+	return "", typeName
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"fmt"
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// decorator is the intermediate representation of a generated decorator,
+// built from a *types.Interface and consumed by writeDecorator.
+type decorator struct {
+	Package    string
+	Imports    []importSpec
+	Name       string
+	TypeParams []typeParam
+	Methods    []decoratorMethod
+	Style      hookStyle
+}
+
+// hookStyle selects the shape of the generated Decorator hook.
+type hookStyle string
+
+const (
+	// hookStyleSimple is the original `func(name string, call func() error) error` hook.
+	hookStyleSimple hookStyle = "simple"
+	// hookStyleRich is the context- and argument-aware hook added for -style=rich.
+	hookStyleRich hookStyle = "rich"
+)
+
+// typeParam is one type parameter of a generic interface, e.g. the T in
+// `Repo[T any, K comparable]`.
+type typeParam struct {
+	Name       string
+	Constraint string
+}
+
+// importSpec is a single import line. Name is the local alias and is
+// empty when the default package name applies.
+type importSpec struct {
+	Name string
+	Path string
+}
+
+type decoratorMethod struct {
+	Name       string
+	Params     []field
+	Results    []field
+	ReturnsErr bool
+	HasCtx     bool
+}
+
+type field struct {
+	Name string
+	Type string
+}
+
+// buildDecorator walks the method set of obj's interface type and turns it
+// into the IR writeDecorator renders. types.Interface.NumMethods/Method
+// already flatten embedded interfaces - including ones from other
+// packages, such as io.Reader - merging and deduplicating their methods
+// by name; a genuine signature conflict between two embedded interfaces
+// is a compile error the type checker catches while loadPackages builds
+// iface, so by the time buildDecorator runs, the method set is already
+// known to be consistent.
+func buildDecorator(obj *types.TypeName, pkg *packages.Package, style hookStyle, useAny bool) *decorator {
+	iface := obj.Type().Underlying().(*types.Interface)
+
+	imports := newImportCollector(pkg.Types)
+	methods := make([]decoratorMethod, iface.NumMethods())
+	for i := 0; i < iface.NumMethods(); i++ {
+		methods[i] = buildMethod(iface.Method(i), imports.qualifier, useAny)
+	}
+
+	if style == hookStyleRich {
+		// the rich Decorator hook signature itself references
+		// context.Context, independent of whether any method does.
+		imports.ensure("context")
+	}
+
+	return &decorator{
+		Package:    pkg.Types.Name(),
+		Imports:    imports.list(),
+		Name:       obj.Name(),
+		TypeParams: buildTypeParams(obj, imports.qualifier, useAny),
+		Methods:    methods,
+		Style:      style,
+	}
+}
+
+// buildTypeParams reads the type parameter list off a generic interface
+// declaration, e.g. `type Repo[T any, K comparable] interface { ... }`.
+// It returns nil for an ordinary, non-generic interface.
+func buildTypeParams(obj *types.TypeName, q types.Qualifier, useAny bool) []typeParam {
+	named, ok := obj.Type().(*types.Named)
+	if !ok {
+		return nil
+	}
+
+	tparams := named.TypeParams()
+	result := make([]typeParam, tparams.Len())
+	for i := 0; i < tparams.Len(); i++ {
+		tp := tparams.At(i)
+		result[i] = typeParam{
+			Name:       tp.Obj().Name(),
+			Constraint: normalizeType(types.TypeString(tp.Constraint(), q), useAny),
+		}
+	}
+	return result
+}
+
+func buildMethod(fn *types.Func, q types.Qualifier, useAny bool) decoratorMethod {
+	sig := fn.Type().(*types.Signature)
+
+	params := buildParams(sig.Params(), sig.Variadic(), q, useAny)
+	results := buildResults(sig.Results(), q, useAny)
+
+	return decoratorMethod{
+		Name:       fn.Name(),
+		Params:     params,
+		Results:    results,
+		ReturnsErr: methodReturnsError(results),
+		HasCtx:     sig.Params().Len() > 0 && isContextContext(sig.Params().At(0).Type()),
+	}
+}
+
+// isContextContext reports whether t is context.Context, by identity
+// rather than by string so an unrelated local type named Context never
+// matches.
+func isContextContext(t types.Type) bool {
+	named, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == "context" && obj.Name() == "Context"
+}
+
+func buildParams(tuple *types.Tuple, variadic bool, q types.Qualifier, useAny bool) []field {
+	n := tuple.Len()
+	fields := make([]field, n)
+	for i := 0; i < n; i++ {
+		typeStr := types.TypeString(tuple.At(i).Type(), q)
+		if variadic && i == n-1 {
+			// the tuple holds the slice type []T for a ...T parameter;
+			// swap the brackets back out for the ellipsis.
+			typeStr = "..." + strings.TrimPrefix(typeStr, "[]")
+		}
+		fields[i] = field{Name: fmt.Sprintf("p%d", i), Type: normalizeType(typeStr, useAny)}
+	}
+	return fields
+}
+
+func buildResults(tuple *types.Tuple, q types.Qualifier, useAny bool) []field {
+	n := tuple.Len()
+	fields := make([]field, n)
+	for i := 0; i < n; i++ {
+		typeStr := normalizeType(types.TypeString(tuple.At(i).Type(), q), useAny)
+		name := fmt.Sprintf("v%d", i)
+		if i == n-1 && typeStr == "error" {
+			name = "err"
+		}
+		fields[i] = field{Name: name, Type: typeStr}
+	}
+	return fields
+}
+
+// normalizeType rewrites bare `interface{}` occurrences in typeStr to
+// `any` when useAny is set, including nested ones like
+// `map[string]interface{}` or `func(interface{}) interface{}`. A
+// non-empty interface literal renders with its members between the
+// braces (e.g. `interface{ Foo() }`), so the exact substring
+// `interface{}` never matches one and it is left untouched.
+func normalizeType(typeStr string, useAny bool) string {
+	if !useAny {
+		return typeStr
+	}
+	return strings.ReplaceAll(typeStr, "interface{}", "any")
+}
+
+func methodReturnsError(results []field) bool {
+	if len(results) == 0 {
+		return false
+	}
+	return results[len(results)-1].Type == "error"
+}
+
+// importCollector tracks the packages referenced while qualifying types
+// for a decorator, so the generated file imports exactly what it uses
+// rather than guessing from string prefixes.
+type importCollector struct {
+	self *types.Package
+	seen map[string]importSpec
+}
+
+func newImportCollector(self *types.Package) *importCollector {
+	return &importCollector{self: self, seen: map[string]importSpec{}}
+}
+
+// qualifier is a types.Qualifier bound to self: it records every foreign
+// package it is asked about and returns the name that should prefix the
+// type in the generated source.
+func (c *importCollector) qualifier(pkg *types.Package) string {
+	if pkg == nil || pkg == c.self {
+		return ""
+	}
+
+	if _, ok := c.seen[pkg.Path()]; !ok {
+		c.seen[pkg.Path()] = importSpec{Path: pkg.Path()}
+	}
+	return pkg.Name()
+}
+
+// ensure records path as an import even if no qualified type ever
+// referenced it.
+func (c *importCollector) ensure(path string) {
+	if _, ok := c.seen[path]; !ok {
+		c.seen[path] = importSpec{Path: path}
+	}
+}
+
+func (c *importCollector) list() []importSpec {
+	specs := make([]importSpec, 0, len(c.seen))
+	for _, spec := range c.seen {
+		specs = append(specs, spec)
+	}
+	sort.Slice(specs, func(i, j int) bool { return specs[i].Path < specs[j].Path })
+	return specs
+}
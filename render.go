@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"log"
+	"sort"
+	"strings"
+)
+
+// codeGeneratedHeader is the warning comment every generated file starts
+// with. It matches the regexp go/build uses to detect generated files
+// (`^// Code generated .* DO NOT EDIT\.$`), so linters and coverage tools
+// skip it.
+const codeGeneratedHeader = "// Code generated by go-decorator; DO NOT EDIT.\n"
+
+// writeDecorator renders the full generated source file for a single
+// decorator. Multiple decorators bound for the same file go through
+// renderFile instead.
+func writeDecorator(d *decorator) string {
+	return renderFile([]*decorator{d})
+}
+
+// renderFile renders one or more decorators into a single source file,
+// sharing one package clause and one deduplicated import block. All decs
+// must belong to the same package.
+func renderFile(decs []*decorator) string {
+	b := new(bytes.Buffer)
+
+	fmt.Fprint(b, codeGeneratedHeader)
+	fmt.Fprintf(b, "package %s\n", decs[0].Package)
+
+	imports := mergeImports(decs)
+	if len(imports) > 0 {
+		fmt.Fprintf(b, "import (\n")
+		for _, imp := range imports {
+			if imp.Name != "" {
+				fmt.Fprintf(b, "\t%s %q\n", imp.Name, imp.Path)
+			} else {
+				fmt.Fprintf(b, "\t%q\n", imp.Path)
+			}
+		}
+		fmt.Fprintf(b, ")\n")
+	}
+
+	if hasRichStyle(decs) {
+		fmt.Fprint(b, methodCallType)
+	}
+
+	for _, d := range decs {
+		decl := typeParamDecl(d.TypeParams)
+		use := typeParamUse(d.TypeParams)
+
+		if d.Style == hookStyleRich {
+			fmt.Fprintf(b, structFormatRich, d.Name, decl, use)
+			for _, method := range d.Methods {
+				fmt.Fprint(b, writeRichMethod(method, d.Name, use))
+			}
+			continue
+		}
+
+		fmt.Fprintf(b, structFormat, d.Name, decl, use)
+		for _, method := range d.Methods {
+			fmt.Fprint(b, writeMethod(method, d.Name, use))
+		}
+	}
+
+	return string(formatSource(b))
+}
+
+// mergeImports dedupes import specs by path across every decorator bound
+// for the same file, keeping the first alias seen for a given path.
+func mergeImports(decs []*decorator) []importSpec {
+	seen := map[string]importSpec{}
+	for _, d := range decs {
+		for _, imp := range d.Imports {
+			if _, ok := seen[imp.Path]; !ok {
+				seen[imp.Path] = imp
+			}
+		}
+	}
+
+	merged := make([]importSpec, 0, len(seen))
+	for _, imp := range seen {
+		merged = append(merged, imp)
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Path < merged[j].Path })
+	return merged
+}
+
+func hasRichStyle(decs []*decorator) bool {
+	for _, d := range decs {
+		if d.Style == hookStyleRich {
+			return true
+		}
+	}
+	return false
+}
+
+func writeMethod(method decoratorMethod, name, typeParamUse string) string {
+	var template string
+	if method.ReturnsErr {
+		template = methodFormatWithErr
+	} else {
+		template = methodFormatNoErr
+	}
+
+	params := formatNameAndType(method.Params)
+	returns := formatNameAndType(method.Results)
+	passArgs := formatNames(method.Params, true)
+	returnArgs := formatNames(method.Results, false)
+
+	equals := ""
+	if len(method.Results) > 0 {
+		equals = "="
+	}
+
+	return fmt.Sprintf(template, name, method.Name, params, returns, returnArgs, passArgs, equals, typeParamUse)
+}
+
+// typeParamDecl renders a generic interface's type parameters as they
+// appear in a declaration, e.g. "[T any, K comparable]". Returns "" for a
+// non-generic interface.
+func typeParamDecl(params []typeParam) string {
+	if len(params) == 0 {
+		return ""
+	}
+	parts := make([]string, len(params))
+	for i, p := range params {
+		parts[i] = fmt.Sprintf("%s %s", p.Name, p.Constraint)
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+// typeParamUse renders a generic interface's type parameters as they
+// appear when instantiating or referring back to the type, e.g.
+// "[T, K]". Returns "" for a non-generic interface.
+func typeParamUse(params []typeParam) string {
+	if len(params) == 0 {
+		return ""
+	}
+	names := make([]string, len(params))
+	for i, p := range params {
+		names[i] = p.Name
+	}
+	return "[" + strings.Join(names, ", ") + "]"
+}
+
+func formatNameAndType(fields []field) string {
+	parts := make([]string, len(fields))
+	for i, f := range fields {
+		parts[i] = fmt.Sprintf("%s %s", f.Name, f.Type)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func formatNames(fields []field, expandEllipsis bool) string {
+	parts := make([]string, len(fields))
+	for i, f := range fields {
+		name := f.Name
+		if expandEllipsis && strings.HasPrefix(f.Type, "...") {
+			name += "..."
+		}
+		parts[i] = name
+	}
+	return strings.Join(parts, ", ")
+}
+
+func formatSource(buf *bytes.Buffer) []byte {
+	// use go/format to properly indent the code and sort imports.
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		// Our generated code should not be invalid go, but
+		// but this error does happen while developing this project.
+		// The user can compile the output to see the error.
+		log.Printf("warning: internal error: invalid Go generated: %s", err)
+		log.Printf("warning: compile the package to analyze the error")
+		return buf.Bytes()
+	}
+	return src
+}
+
+// [1] - interface name `Sample`
+// [2] - type param declaration `[T any, K comparable]`, empty if not generic
+// [3] - type param use `[T, K]`, empty if not generic
+const structFormat = `type %[1]sDecorator%[2]s struct {
+	Inner %[1]s%[3]s
+	Decorator func(name string, call func() error) error
+}
+`
+
+// [1] - interface name `Sample`
+// [2] - method name `Call`
+// [3] - method params `s1 string, s2 func(int)`
+// [4] - method returns `(string, error)`
+// [5] - assign temp variables
+// [6] - pass method arguments to inner method
+// [7] - optional equals sign
+// [8] - type param use `[T, K]`, empty if not generic
+const methodFormatWithErr = `func (this * %[1]sDecorator%[8]s) %[2]s(%[3]s) (%[4]s) {
+	call := func() error {
+		var err error
+		%[5]s %[7]s this.Inner.%[2]s(%[6]s)
+		return err
+	}
+	err = this.Decorator("%[2]s", call)
+	return %[5]s
+}
+`
+
+// [1] - interface name `Sample`
+// [2] - method name `Call`
+// [3] - method params `s1 string, s2 func(int)`
+// [4] - method returns `(string, error)`
+// [5] - assign temp variables
+// [6] - pass method arguments to inner method
+// [7] - optional equals sign
+// [8] - type param use `[T, K]`, empty if not generic
+const methodFormatNoErr = `func (this * %[1]sDecorator%[8]s) %[2]s(%[3]s) (%[4]s) {
+	call := func() error {
+		%[5]s %[7]s this.Inner.%[2]s(%[6]s)
+		return nil
+	}
+	_ = this.Decorator("%[2]s", call)
+	return %[5]s
+}
+`
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// methodCallType is emitted once per file when -style=rich, ahead of the
+// decorator struct.
+const methodCallType = `type MethodCall struct {
+	Method  string
+	Args    []any
+	Results []any
+}
+`
+
+// [1] - interface name `Sample`
+// [2] - type param declaration `[T any, K comparable]`, empty if not generic
+// [3] - type param use `[T, K]`, empty if not generic
+const structFormatRich = `type %[1]sDecorator%[2]s struct {
+	Inner %[1]s%[3]s
+	Decorator func(ctx context.Context, mc *MethodCall, invoke func(context.Context) error) error
+}
+`
+
+// writeRichMethod renders one decorator method for -style=rich: it threads
+// a leading context.Context parameter through to invoke (synthesizing
+// context.Background() when the interface method has none), records the
+// call's arguments and zero-valued results on a *MethodCall, and copies
+// mc.Results back into the named return variables after the hook runs so
+// a hook can short-circuit invoke entirely (caching, retries, circuit
+// breaking) without the generated code changing.
+func writeRichMethod(method decoratorMethod, name, typeParamUse string) string {
+	dataParams := method.Params
+	ctxExpr := "context.Background()"
+	if method.HasCtx {
+		ctxExpr = method.Params[0].Name
+		dataParams = method.Params[1:]
+	}
+
+	args := make([]string, len(dataParams))
+	for i, p := range dataParams {
+		args[i] = fmt.Sprintf("any(%s)", p.Name)
+	}
+
+	resultNames := formatNames(method.Results, false)
+	passArgs := richPassArgs(method.Params, method.HasCtx)
+
+	callAssign := ""
+	invokeReturn := "return nil"
+	decoratorAssign := "_ = "
+	if len(method.Results) > 0 {
+		callAssign = resultNames + " = "
+	}
+	if method.ReturnsErr {
+		invokeReturn = "return err"
+		decoratorAssign = "err = "
+	}
+
+	var mcAssign strings.Builder
+	for i, r := range method.Results {
+		fmt.Fprintf(&mcAssign, "\t\tmc.Results[%d] = %s\n", i, r.Name)
+	}
+
+	var copyBack strings.Builder
+	for i, r := range method.Results {
+		fmt.Fprintf(&copyBack, "\tif v, ok := mc.Results[%d].(%s); ok {\n\t\t%s = v\n\t}\n", i, r.Type, r.Name)
+	}
+
+	b := new(strings.Builder)
+	fmt.Fprintf(b, "func (this * %sDecorator%s) %s(%s) (%s) {\n",
+		name, typeParamUse, method.Name, formatNameAndType(method.Params), formatNameAndType(method.Results))
+	fmt.Fprintf(b, "\tctx := %s\n", ctxExpr)
+	fmt.Fprintf(b, "\tmc := &MethodCall{\n\t\tMethod:  %q,\n\t\tArgs:    []any{%s},\n\t\tResults: []any{%s},\n\t}\n",
+		method.Name, strings.Join(args, ", "), resultNames)
+	fmt.Fprintf(b, "\tinvoke := func(ctx context.Context) error {\n")
+	fmt.Fprintf(b, "\t\t%sthis.Inner.%s(%s)\n", callAssign, method.Name, passArgs)
+	fmt.Fprint(b, mcAssign.String())
+	fmt.Fprintf(b, "\t\t%s\n\t}\n", invokeReturn)
+	fmt.Fprintf(b, "\t%sthis.Decorator(ctx, mc, invoke)\n", decoratorAssign)
+	fmt.Fprint(b, copyBack.String())
+	fmt.Fprintf(b, "\treturn %s\n}\n", resultNames)
+	return b.String()
+}
+
+// richPassArgs renders the arguments forwarded to the wrapped interface's
+// method from inside invoke: the leading context.Context parameter, if
+// any, is replaced by invoke's own ctx so a hook that swaps in a
+// cancelled or enriched context is honored by the inner call.
+func richPassArgs(params []field, hasCtx bool) string {
+	names := make([]string, len(params))
+	last := len(params) - 1
+	for i, p := range params {
+		name := p.Name
+		switch {
+		case hasCtx && i == 0:
+			name = "ctx"
+		case i == last && strings.HasPrefix(p.Type, "..."):
+			name += "..."
+		}
+		names[i] = name
+	}
+	return strings.Join(names, ", ")
+}
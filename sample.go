@@ -0,0 +1,30 @@
+package main
+
+import (
+	"encoding/base64"
+	"os"
+	"strings"
+)
+
+// Sample is the set of test cases for this program.  To test:
+// > go run . -type Sample .
+// > go build
+type Sample interface {
+	Do()
+	Maybe() error
+	Repeat(s, t, v string)
+	Arg1(t string, f func(*int)) (e error)
+	Out1([]byte, map[string]func() int) (string, error)
+	Complex(**OtherString) (*[]SampleStruct, error)
+	Remote(os.File) ([]strings.Reader, *os.File, error)
+	Interface(anything interface{}) string
+	Range(format string, args ...interface{})
+	Prefix(base64.Encoding) error
+}
+
+type OtherString string
+
+type SampleStruct struct {
+	Val  string
+	File *os.File
+}
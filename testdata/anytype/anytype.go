@@ -0,0 +1,13 @@
+// Package anytype is a fixture for go-decorator's `any`-normalization
+// golden test.
+package anytype
+
+// Sample exercises bare interface{} in nested positions, alongside a
+// non-empty interface literal that must be left untouched.
+type Sample interface {
+	Interface(anything interface{}) string
+	Range(args ...interface{})
+	Map(m map[string]interface{}) []interface{}
+	Func(f func(interface{}) interface{}) error
+	Mixed(v interface{ String() string }) interface{ String() string }
+}
@@ -0,0 +1,14 @@
+// Package embedding is a fixture for go-decorator's embedded interface
+// golden test.
+package embedding
+
+import "io"
+
+// Sample embeds two standard library interfaces alongside a method of its
+// own, so its complete method set spans packages that never appear
+// syntactically in this file.
+type Sample interface {
+	io.Reader
+	io.Closer
+	Extra() error
+}
@@ -0,0 +1,19 @@
+// Package generics is a fixture for go-decorator's generic interface
+// golden test.
+package generics
+
+// Numeric is a constraint with a union of underlying kinds, exercised by
+// Repo's second type parameter.
+type Numeric interface {
+	~int | ~int64 | ~float64
+}
+
+// Repo is a generic interface fixture with multiple type parameters,
+// instantiated generic types in its signatures, and a method-level
+// constraint reference.
+type Repo[T any, K Numeric] interface {
+	Get(K) (T, error)
+	List(ids ...K) ([]T, error)
+	Store(items map[K]T) error
+	Watch(updates chan T) error
+}
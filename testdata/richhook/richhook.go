@@ -0,0 +1,13 @@
+// Package richhook is a fixture for go-decorator's rich Decorator hook
+// golden test.
+package richhook
+
+import "context"
+
+// Sample exercises the rich hook: a context-leading method, a method with
+// no context, and one with multiple non-error results.
+type Sample interface {
+	Fetch(ctx context.Context, id string) (string, error)
+	Close() error
+	Split(s string) (string, string)
+}